@@ -0,0 +1,63 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Account is the owner of Applications and Tasks.
+type Account struct {
+	// ID is the ID of the Account.
+	ID bson.ObjectId `bson:"_id"`
+
+	// APIKey is the secret key used to authenticate requests for this Account.
+	APIKey string `bson:"api_key"`
+}
+
+func newAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewAccount creates a new Account.
+func (b *Base) NewAccount() (account *Account, err error) {
+	apiKey, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	account = &Account{
+		ID:     bson.NewObjectId(),
+		APIKey: apiKey,
+	}
+	err = b.db.C("accounts").Insert(account)
+	return
+}
+
+// AuthenticateAccount returns true if key is the Account's API key.
+func (b *Base) AuthenticateAccount(accountID bson.ObjectId, key string) (bool, error) {
+	account := &Account{}
+	err := b.db.C("accounts").FindId(accountID).One(account)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return account.APIKey == key, nil
+}
+
+// EnsureAccountIndex creates mongo indexes for Account.
+func (b *Base) EnsureAccountIndex() {
+	index := mgo.Index{
+		Key:        []string{"api_key"},
+		Unique:     true,
+		Background: false,
+	}
+	b.db.C("accounts").EnsureIndex(index)
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Application is a named group of Tasks belonging to an Account.
+type Application struct {
+	// ID is the ID of the Application.
+	ID bson.ObjectId `bson:"_id"`
+
+	// Account is the ID of the Account owning the Application.
+	Account bson.ObjectId `bson:"account"`
+
+	// Name is the application's name.
+	Name string `bson:"name"`
+
+	// Retention is the default RetentionPolicy applied to the Application's
+	// Tasks and Attempts, unless a Task defines its own override.
+	Retention RetentionPolicy `bson:"retention,omitempty"`
+
+	// Deleted marks the Application as soft-deleted.
+	Deleted bool `bson:"deleted"`
+}
+
+// NewApplication creates, or un-deletes, an Application.
+func (b *Base) NewApplication(account bson.ObjectId, name string) (application *Application, err error) {
+	application = &Application{
+		ID:      bson.NewObjectId(),
+		Account: account,
+		Name:    name,
+	}
+	err = b.db.C("applications").Insert(application)
+	if mgo.IsDup(err) {
+		change := mgo.Change{
+			Update: bson.M{
+				"$set": bson.M{"deleted": false},
+			},
+			ReturnNew: true,
+		}
+		query := bson.M{
+			"account": account,
+			"name":    name,
+		}
+		_, err = b.db.C("applications").Find(query).Apply(change, application)
+	}
+	return
+}
+
+// GetApplication returns an Application.
+func (b *Base) GetApplication(account bson.ObjectId, name string) (application *Application, err error) {
+	query := bson.M{
+		"account": account,
+		"name":    name,
+		"deleted": false,
+	}
+	application = &Application{}
+	err = b.db.C("applications").Find(query).One(application)
+	if err == mgo.ErrNotFound {
+		err = nil
+		application = nil
+	}
+	return
+}
+
+// EnsureApplicationIndex creates mongo indexes for Application.
+func (b *Base) EnsureApplicationIndex() {
+	index := mgo.Index{
+		Key:        []string{"account", "name"},
+		Unique:     true,
+		Background: false,
+		Sparse:     true,
+	}
+	b.db.C("applications").EnsureIndex(index)
+}
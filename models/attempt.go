@@ -0,0 +1,333 @@
+package models
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MaxInlineResponseBodySize is the maximum number of bytes of a Task's HTTP
+// response body kept inline on the Attempt. Bigger bodies are truncated
+// inline and the full body is kept in the "attempt_bodies" GridFS bucket.
+const MaxInlineResponseBodySize = 64 * 1024
+
+// AttemptStatuses are the different statuses that an Attempt can have. This
+// is a superset of TaskStatuses, adding `claimed`, which only ever applies
+// to an Attempt between a worker's claim and its completion report.
+var AttemptStatuses = map[string]bool{
+	"pending":  true,
+	"claimed":  true,
+	"retrying": true,
+	"canceled": true,
+	"success":  true,
+	"error":    true,
+}
+
+// Attempt describes a single execution of a Task, successful or not. Where
+// Task is the recurring policy, Attempt is the record of one run of it.
+type Attempt struct {
+	// ID is the ID of the Attempt.
+	ID bson.ObjectId `bson:"_id"`
+
+	// Account is the ID of the Account owning the Attempt.
+	Account bson.ObjectId `bson:"account"`
+
+	// Application is the name of the parent Application.
+	Application string `bson:"application"`
+
+	// Task is the name of the parent Task.
+	Task string `bson:"task"`
+
+	// TaskID is the ID of the parent Task.
+	TaskID bson.ObjectId `bson:"task_id"`
+
+	// ExecutionID is the ID of the parent Execution, if this Attempt was
+	// created as part of a batch run rather than the Task's own schedule.
+	ExecutionID bson.ObjectId `bson:"execution_id,omitempty"`
+
+	// Queue is the name of the parent Queue.
+	Queue string `bson:"queue"`
+
+	// WorkerID is the ID of the Worker that claimed this Attempt, set by
+	// ClaimNextAttempt and checked by CompleteAttempt so that only the
+	// claiming Worker can report its completion.
+	WorkerID bson.ObjectId `bson:"worker_id,omitempty"`
+
+	// Internal marks an Attempt generated by hooky itself, such as a
+	// completion callback, rather than a regular scheduled execution of the
+	// Task.
+	Internal bool `bson:"internal,omitempty"`
+
+	// URL overrides the parent Task's URL. It is only set on internal
+	// attempts; regular attempts are dispatched using the Task's own URL.
+	URL string `bson:"url,omitempty"`
+
+	// Method overrides the parent Task's Method. Only set on internal attempts.
+	Method string `bson:"method,omitempty"`
+
+	// Headers overrides the parent Task's Headers. Only set on internal attempts.
+	Headers map[string]string `bson:"headers,omitempty"`
+
+	// Payload overrides the parent Task's Payload. Only set on internal attempts.
+	Payload string `bson:"payload,omitempty"`
+
+	// RetryNumber is the retry number of this Attempt, 0 for the first attempt.
+	RetryNumber int `bson:"retry_number"`
+
+	// Started is the Unix timestamp of when the request was sent.
+	Started int64 `bson:"started,omitempty"`
+
+	// Finished is the Unix timestamp of when the response, or error, was received.
+	Finished int64 `bson:"finished,omitempty"`
+
+	// DurationMs is the duration of the request in milliseconds.
+	DurationMs int64 `bson:"duration_ms,omitempty"`
+
+	// HTTPStatus is the HTTP status code of the response if any.
+	HTTPStatus int `bson:"http_status,omitempty"`
+
+	// ResponseHeaders are the HTTP headers of the response if any.
+	ResponseHeaders map[string][]string `bson:"response_headers,omitempty"`
+
+	// ResponseBody is the response body, truncated to MaxInlineResponseBodySize.
+	ResponseBody []byte `bson:"response_body,omitempty"`
+
+	// ResponseBodySize is the size in bytes of the full, untruncated response body.
+	ResponseBodySize int `bson:"response_body_size,omitempty"`
+
+	// ResponseBodyTruncated is true when ResponseBody does not hold the full
+	// body and the full body was spilled to GridFS under the Attempt's ID.
+	ResponseBodyTruncated bool `bson:"response_body_truncated,omitempty"`
+
+	// Error is the error message if the request could not be completed.
+	Error string `bson:"error,omitempty"`
+
+	// Status is either `pending`, `claimed`, `retrying`, `canceled`, `success`
+	// or `error`.
+	Status string `bson:"status"`
+
+	// NextRetryAt is the Unix timestamp of the next scheduled attempt, if any.
+	NextRetryAt int64 `bson:"next_retry_at,omitempty"`
+
+	// Deleted marks the Attempt as soft-deleted.
+	Deleted bool `bson:"deleted"`
+
+	// DeletedAt is the timestamp at which Deleted was set to true.
+	DeletedAt int64 `bson:"deleted_at,omitempty"`
+}
+
+// NewAttempt creates and stores a new pending Attempt for the given Task.
+// executionID tags the Attempt as belonging to an Execution batch, including
+// the retries of an Attempt of that batch, or is left empty for a Task's own
+// scheduled Attempts and their retries.
+func (b *Base) NewAttempt(task *Task, executionID bson.ObjectId) (attempt *Attempt, err error) {
+	attempt = &Attempt{
+		ID:          bson.NewObjectId(),
+		Account:     task.Account,
+		Application: task.Application,
+		Task:        task.Name,
+		TaskID:      task.ID,
+		ExecutionID: executionID,
+		Queue:       task.Queue,
+		RetryNumber: task.Retry.Attempts,
+		Status:      "pending",
+		NextRetryAt: task.At / int64(time.Second),
+	}
+	err = b.db.C("attempts").Insert(attempt)
+	return
+}
+
+// DeletePendingAttempts soft-deletes any attempt still pending or retrying
+// for the given Task, returning whether any were deleted.
+func (b *Base) DeletePendingAttempts(taskID bson.ObjectId) (deleted bool, err error) {
+	query := bson.M{
+		"task_id": taskID,
+		"status":  bson.M{"$in": []string{"pending", "retrying"}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"deleted":    true,
+			"deleted_at": time.Now().Unix(),
+		},
+	}
+	info, err := b.db.C("attempts").UpdateAll(query, update)
+	if err == nil && info != nil {
+		deleted = info.Updated > 0
+	}
+	return
+}
+
+// GetAttempt returns an Attempt given its ID.
+func (b *Base) GetAttempt(account bson.ObjectId, attemptID bson.ObjectId) (attempt *Attempt, err error) {
+	query := bson.M{
+		"_id":     attemptID,
+		"account": account,
+		"deleted": false,
+	}
+	attempt = &Attempt{}
+	err = b.db.C("attempts").Find(query).One(attempt)
+	if err == mgo.ErrNotFound {
+		err = nil
+		attempt = nil
+	}
+	return
+}
+
+// GetAttempts returns a list of Attempts for an Application, optionally
+// restricted to a single Task, honoring the `status`, `since`, `until` and
+// `min_status_code` filters. Results default to `_id` order, matching the
+// keyset cursor's own walk order, so `nextCursor`/`prevCursor` are usable on
+// the very first page; `since`/`until` still filter on `started`, they just
+// don't change the sort.
+func (b *Base) GetAttempts(account bson.ObjectId, application string, taskName string, lp ListParams, lr *ListResult) (err error) {
+	query := bson.M{
+		"account":     account,
+		"application": application,
+		"deleted":     false,
+	}
+	if taskName != "" {
+		query["task"] = taskName
+	}
+	if value, ok := lp.Filters["status"]; ok {
+		if _, ok := AttemptStatuses[value]; ok {
+			query["status"] = value
+		}
+	}
+	if value, ok := lp.Filters["min_status_code"]; ok {
+		if min, err := strconv.Atoi(value); err == nil {
+			query["http_status"] = bson.M{"$gte": min}
+		}
+	}
+	created := bson.M{}
+	if value, ok := lp.Filters["since"]; ok {
+		if since, err := strconv.ParseInt(value, 10, 64); err == nil {
+			created["$gte"] = since
+		}
+	}
+	if value, ok := lp.Filters["until"]; ok {
+		if until, err := strconv.ParseInt(value, 10, 64); err == nil {
+			created["$lte"] = until
+		}
+	}
+	if len(created) > 0 {
+		query["started"] = created
+	}
+	return b.getItems("attempts", query, lp, lr)
+}
+
+// OpenResponseBody returns a reader over the full response body of the
+// Attempt along with its size, falling back to GridFS when the body was
+// truncated inline.
+func (b *Base) OpenResponseBody(attempt *Attempt) (io.ReadCloser, int64, error) {
+	if !attempt.ResponseBodyTruncated {
+		return ioutil.NopCloser(bytes.NewReader(attempt.ResponseBody)), int64(len(attempt.ResponseBody)), nil
+	}
+	gridFile, err := b.db.GridFS("attempt_bodies").OpenId(attempt.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return gridFile, gridFile.Size(), nil
+}
+
+// storeResponseBody spills the full response body of an Attempt to the
+// "attempt_bodies" GridFS bucket, keyed by the Attempt's own ID.
+func (b *Base) storeResponseBody(attemptID bson.ObjectId, body []byte) error {
+	gridFile, err := b.db.GridFS("attempt_bodies").Create("")
+	if err != nil {
+		return err
+	}
+	gridFile.SetId(attemptID)
+	if _, err = gridFile.Write(body); err != nil {
+		gridFile.Close()
+		return err
+	}
+	return gridFile.Close()
+}
+
+// CompleteAttempt records the HTTP result of a claimed Attempt, as reported
+// by the worker that executed it, and advances the parent Task to its next
+// Attempt or terminal state via NextAttemptForTask; Internal Attempts, such
+// as callbacks, are recorded but do not advance the Task. httpStatus is ignored
+// when errMsg is set, since the request never completed. Attempts that are
+// not currently `claimed`, or that were claimed by a different Worker, are
+// left untouched and reported back as nil, the same as a not-found Attempt.
+func (b *Base) CompleteAttempt(account bson.ObjectId, workerID bson.ObjectId, attemptID bson.ObjectId, httpStatus int, responseHeaders map[string][]string, responseBody []byte, errMsg string) (attempt *Attempt, err error) {
+	attempt, err = b.GetAttempt(account, attemptID)
+	if err != nil || attempt == nil {
+		return attempt, err
+	}
+	if attempt.Status != "claimed" || attempt.WorkerID != workerID {
+		return nil, nil
+	}
+
+	if errMsg != "" {
+		httpStatus = 0
+	}
+	status := "success"
+	if errMsg != "" || httpStatus >= 400 {
+		status = "error"
+	}
+
+	body := responseBody
+	truncated := false
+	if len(body) > MaxInlineResponseBodySize {
+		if err = b.storeResponseBody(attempt.ID, body); err != nil {
+			return nil, err
+		}
+		body = body[:MaxInlineResponseBodySize]
+		truncated = true
+	}
+
+	now := time.Now().Unix()
+	set := bson.M{
+		"status":                  status,
+		"finished":                now,
+		"duration_ms":             (now - attempt.Started) * 1000,
+		"http_status":             httpStatus,
+		"response_headers":        responseHeaders,
+		"response_body":           body,
+		"response_body_size":      len(responseBody),
+		"response_body_truncated": truncated,
+		"error":                   errMsg,
+	}
+	change := mgo.Change{
+		Update:    bson.M{"$set": set},
+		ReturnNew: true,
+	}
+	if _, err = b.db.C("attempts").FindId(attempt.ID).Apply(change, attempt); err != nil {
+		return nil, err
+	}
+
+	if attempt.Internal {
+		return attempt, nil
+	}
+	if _, err = b.NextAttemptForTask(attempt.TaskID, status); err != nil {
+		return attempt, err
+	}
+	return attempt, nil
+}
+
+// EnsureAttemptIndex creates mongo indexes for Attempt.
+func (b *Base) EnsureAttemptIndex() {
+	index := mgo.Index{
+		Key:        []string{"account", "application", "task", "-started"},
+		Background: true,
+	}
+	b.db.C("attempts").EnsureIndex(index)
+	executionIndex := mgo.Index{
+		Key:        []string{"execution_id"},
+		Sparse:     true,
+		Background: true,
+	}
+	b.db.C("attempts").EnsureIndex(executionIndex)
+	dispatchIndex := mgo.Index{
+		Key:        []string{"account", "queue", "status", "_id"},
+		Background: true,
+	}
+	b.db.C("attempts").EnsureIndex(dispatchIndex)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"gopkg.in/mgo.v2"
+)
+
+// Base wraps the MongoDB database handle shared by all the models.
+type Base struct {
+	db *mgo.Database
+}
+
+// NewBase creates a new Base bound to the given database.
+func NewBase(db *mgo.Database) *Base {
+	return &Base{db: db}
+}
+
+// EnsureIndex creates all the Mongo indexes used by the models.
+func (b *Base) EnsureIndex() {
+	b.EnsureAccountIndex()
+	b.EnsureApplicationIndex()
+	b.EnsureTaskIndex()
+	b.EnsureAttemptIndex()
+	b.EnsureWorkerIndex()
+	b.EnsureBreakerCounterIndex()
+	b.EnsureExecutionIndex()
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// SignatureHeader is the HTTP header carrying the HMAC signature of a
+// callback's body, so that receivers can verify it was sent by hooky.
+const SignatureHeader = "X-Hooky-Signature"
+
+// Callbacks describes the URLs to POST to when a Task transitions to a
+// terminal state.
+type Callbacks struct {
+	// OnSuccess is POSTed to whenever an attempt succeeds.
+	OnSuccess string `bson:"on_success,omitempty" json:"on_success,omitempty"`
+
+	// OnError is POSTed to once Retry.MaxAttempts has been exhausted.
+	OnError string `bson:"on_error,omitempty" json:"on_error,omitempty"`
+
+	// OnStatusChange is POSTed to on every status transition.
+	OnStatusChange string `bson:"on_status_change,omitempty" json:"on_status_change,omitempty"`
+}
+
+// CallbackPayload is the JSON body POSTed to a Task's callback URLs.
+type CallbackPayload struct {
+	// TaskID is the ID of the Task that transitioned.
+	TaskID string `json:"taskId"`
+
+	// Status is the Task's new status.
+	Status string `json:"status"`
+
+	// Errors is the total number of attempts that have failed.
+	Errors int `json:"errors"`
+
+	// LastResponseSummary summarizes the last attempt's response, such as
+	// its HTTP status or error message.
+	LastResponseSummary string `json:"lastResponseSummary,omitempty"`
+}
+
+// responseSummary condenses the terminating Attempt of a Task into the
+// short string carried as CallbackPayload.LastResponseSummary, preferring
+// the transport error, if any, over the HTTP status code.
+func responseSummary(attempt *Attempt) string {
+	if attempt == nil {
+		return ""
+	}
+	if attempt.Error != "" {
+		return attempt.Error
+	}
+	if attempt.HTTPStatus > 0 {
+		return strconv.Itoa(attempt.HTTPStatus)
+	}
+	return ""
+}
+
+// signCallback returns the hex-encoded HMAC-SHA256 of body keyed by the
+// owning Account's API key.
+func signCallback(apiKey string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// enqueueCallback creates and stores an internal Attempt that POSTs a
+// CallbackPayload describing task to url, signed with the owning Account's
+// API key.
+func (b *Base) enqueueCallback(task *Task, url string, lastResponseSummary string) (attempt *Attempt, err error) {
+	account := &Account{}
+	if err = b.db.C("accounts").FindId(task.Account).One(account); err != nil {
+		return nil, err
+	}
+
+	payload := CallbackPayload{
+		TaskID:              task.ID.Hex(),
+		Status:              task.Status,
+		Errors:              task.Errors,
+		LastResponseSummary: lastResponseSummary,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt = &Attempt{
+		ID:          bson.NewObjectId(),
+		Account:     task.Account,
+		Application: task.Application,
+		Task:        task.Name,
+		TaskID:      task.ID,
+		Queue:       task.Queue,
+		Internal:    true,
+		URL:         url,
+		Method:      "POST",
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			SignatureHeader: signCallback(account.APIKey, body),
+		},
+		Payload: string(body),
+		Status:  "pending",
+	}
+	err = b.db.C("attempts").Insert(attempt)
+	return
+}
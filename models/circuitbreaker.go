@@ -0,0 +1,219 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CircuitBreaker auto-disables a Task once it sustains a high error rate,
+// protecting a downstream that has gone bad. The current ErrorRate is
+// computed on every Task but, without a CircuitBreaker, never acted on.
+type CircuitBreaker struct {
+	// MinExecutions is the minimum number of executions within WindowSeconds
+	// before the breaker is allowed to trip.
+	MinExecutions int `bson:"min_executions,omitempty" json:"minExecutions,omitempty"`
+
+	// ErrorRateThreshold is the error rate, in percent (0-100), above which
+	// the breaker trips.
+	ErrorRateThreshold int `bson:"error_rate_threshold,omitempty" json:"errorRateThreshold,omitempty"`
+
+	// WindowSeconds is the size, in seconds, of the rolling window used to
+	// compute the error rate.
+	WindowSeconds int64 `bson:"window_seconds,omitempty" json:"windowSeconds,omitempty"`
+
+	// CooldownSeconds is how long the breaker stays open before it is
+	// eligible to be re-armed.
+	CooldownSeconds int64 `bson:"cooldown_seconds,omitempty" json:"cooldownSeconds,omitempty"`
+
+	// TrippedAt is the Unix timestamp of the last time the breaker tripped,
+	// or 0 if it never has. It is reset to 0 when the breaker is re-armed, so
+	// the time of a trip is not kept once its cooldown has been served.
+	TrippedAt int64 `bson:"tripped_at,omitempty" json:"trippedAt,omitempty"`
+
+	// TripCount is the number of times the breaker has tripped.
+	TripCount int `bson:"trip_count,omitempty" json:"tripCount,omitempty"`
+}
+
+// enabled reports whether the Task defines a CircuitBreaker.
+func (cb *CircuitBreaker) enabled() bool {
+	return cb.WindowSeconds > 0 && cb.ErrorRateThreshold > 0
+}
+
+// State returns the breaker's current state: `closed` when it has never
+// tripped or has been re-armed since, `open` while within CooldownSeconds of
+// its last trip, and
+// `half_open` once the cooldown has elapsed but it hasn't been re-armed yet.
+func (cb *CircuitBreaker) State(now int64) string {
+	if cb.TrippedAt == 0 {
+		return "closed"
+	}
+	if now-cb.TrippedAt < cb.CooldownSeconds {
+		return "open"
+	}
+	return "half_open"
+}
+
+// breakerCounter is a rolling counter document tracking executions and
+// errors for a single `taskID:bucket` window.
+type breakerCounter struct {
+	ID         string    `bson:"_id"`
+	Executions int       `bson:"executions"`
+	Errors     int       `bson:"errors"`
+	ExpireAt   time.Time `bson:"expire_at"`
+}
+
+// recordCircuitBreaker increments the current window's counter for task and
+// reports whether it should trip, i.e. MinExecutions has been reached and
+// the window's error rate exceeds ErrorRateThreshold.
+func (b *Base) recordCircuitBreaker(task *Task, now time.Time, errored bool) (tripped bool, err error) {
+	cb := task.CircuitBreaker
+	if !cb.enabled() {
+		return false, nil
+	}
+
+	bucket := now.Unix() / cb.WindowSeconds
+	key := fmt.Sprintf("%s:%d", task.ID.Hex(), bucket)
+
+	inc := bson.M{"executions": 1}
+	if errored {
+		inc["errors"] = 1
+	}
+	change := mgo.Change{
+		Update: bson.M{
+			"$inc": inc,
+			"$set": bson.M{"expire_at": now.Add(2 * time.Duration(cb.WindowSeconds) * time.Second)},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	counter := &breakerCounter{}
+	if _, err = b.db.C("breaker_counters").FindId(key).Apply(change, counter); err != nil {
+		return false, err
+	}
+
+	if counter.Executions < cb.MinExecutions {
+		return false, nil
+	}
+	errorRate := counter.Errors * 100 / counter.Executions
+	return errorRate > cb.ErrorRateThreshold, nil
+}
+
+// PutTaskCircuitBreaker sets a Task's CircuitBreaker configuration, leaving
+// its trip state untouched.
+func (b *Base) PutTaskCircuitBreaker(account bson.ObjectId, application string, name string, cb CircuitBreaker) (err error) {
+	query := bson.M{
+		"account":     account,
+		"application": application,
+		"name":        name,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"circuit_breaker.min_executions":       cb.MinExecutions,
+			"circuit_breaker.error_rate_threshold": cb.ErrorRateThreshold,
+			"circuit_breaker.window_seconds":       cb.WindowSeconds,
+			"circuit_breaker.cooldown_seconds":     cb.CooldownSeconds,
+		},
+	}
+	_, err = b.db.C("tasks").UpdateAll(query, update)
+	return
+}
+
+// EnsureBreakerCounterIndex creates the TTL index that reaps expired
+// breaker_counters documents.
+func (b *Base) EnsureBreakerCounterIndex() {
+	index := mgo.Index{
+		Key:         []string{"expire_at"},
+		ExpireAfter: 1,
+		Background:  true,
+	}
+	b.db.C("breaker_counters").EnsureIndex(index)
+}
+
+// RearmTrippedBreakers re-activates Tasks whose CircuitBreaker has been open
+// for at least CooldownSeconds, recomputing their next `at`.
+func (b *Base) RearmTrippedBreakers() (rearmed int, err error) {
+	now := time.Now().UTC()
+	query := bson.M{
+		"active":                     false,
+		"circuit_breaker.tripped_at": bson.M{"$gt": 0},
+		"deleted":                    false,
+	}
+	var tasks []*Task
+	if err = b.db.C("tasks").Find(query).All(&tasks); err != nil {
+		return 0, err
+	}
+
+	for _, task := range tasks {
+		cb := task.CircuitBreaker
+		if !cb.enabled() || cb.TrippedAt == 0 || now.Unix()-cb.TrippedAt < cb.CooldownSeconds {
+			continue
+		}
+
+		var at int64
+		if task.Schedule != "" {
+			if at, err = nextRun(task.Schedule); err != nil {
+				continue
+			}
+		} else {
+			at = now.UnixNano()
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"active":                     true,
+				"at":                         at,
+				"circuit_breaker.tripped_at": int64(0),
+			},
+		}
+		if err := b.db.C("tasks").UpdateId(task.ID, update); err != nil {
+			continue
+		}
+		rearmed++
+	}
+	return rearmed, nil
+}
+
+// CircuitBreakerRunner periodically re-arms Tasks whose CircuitBreaker has
+// cooled down.
+type CircuitBreakerRunner struct {
+	db       func() *mgo.Database
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// NewCircuitBreakerRunner creates a CircuitBreakerRunner that opens a fresh
+// database handle via db for every re-arm pass, at the given interval.
+func NewCircuitBreakerRunner(db func() *mgo.Database, interval time.Duration) *CircuitBreakerRunner {
+	return &CircuitBreakerRunner{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Run blocks, re-arming cooled-down breakers at the configured interval,
+// until Stop is called.
+func (cr *CircuitBreakerRunner) Run() {
+	ticker := time.NewTicker(cr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			db := cr.db()
+			if _, err := NewBase(db).RearmTrippedBreakers(); err != nil {
+				fmt.Printf("Error re-arming circuit breakers: %s\n", err)
+			}
+			db.Session.Close()
+		case <-cr.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the runner's re-arm loop.
+func (cr *CircuitBreakerRunner) Stop() {
+	close(cr.stop)
+}
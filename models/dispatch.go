@@ -0,0 +1,109 @@
+package models
+
+import (
+	"path"
+	"sort"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxDispatchCandidates bounds how many pending Attempts are scanned per
+// ClaimNextAttempt call.
+const maxDispatchCandidates = 100
+
+// matchLabels reports whether workerLabels satisfies every one of a Task's
+// required label selectors. Selector values may use shell glob syntax (e.g.
+// `region=eu-*`) to match a family of worker label values.
+func matchLabels(required map[string]string, workerLabels map[string]string) bool {
+	for key, pattern := range required {
+		value, ok := workerLabels[key]
+		if !ok {
+			return false
+		}
+		if matched, err := path.Match(pattern, value); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// preferredScore counts how many of a Task's PreferredLabels a worker
+// satisfies, used to rank otherwise-eligible Attempts when several are
+// pending on the same queue.
+func preferredScore(preferred map[string]string, workerLabels map[string]string) int {
+	score := 0
+	for key, pattern := range preferred {
+		if value, ok := workerLabels[key]; ok {
+			if matched, err := path.Match(pattern, value); err == nil && matched {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// ClaimNextAttempt finds the best pending Attempt on queue whose Task's
+// required Labels are satisfied by workerLabels, marks it claimed and
+// returns it, or returns a nil Attempt when none are eligible. Among
+// eligible Attempts, the one matching the most PreferredLabels is picked
+// first, ties broken by insertion order.
+//
+// The claiming update is conditioned on the Attempt still being `pending`,
+// so that two workers racing for the same candidate can't both claim it:
+// the loser's Apply comes back mgo.ErrNotFound and falls through to the
+// next-best candidate instead of returning a double-claimed Attempt.
+func (b *Base) ClaimNextAttempt(account bson.ObjectId, workerID bson.ObjectId, queue string, workerLabels map[string]string) (attempt *Attempt, err error) {
+	query := bson.M{
+		"account": account,
+		"queue":   queue,
+		"status":  "pending",
+		"deleted": false,
+	}
+	var candidates []*Attempt
+	if err = b.db.C("attempts").Find(query).Sort("_id").Limit(maxDispatchCandidates).All(&candidates); err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		attempt *Attempt
+		score   int
+	}
+	var eligible []scored
+	for _, candidate := range candidates {
+		task, err := b.GetTaskByID(candidate.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil || !matchLabels(task.Labels, workerLabels) {
+			continue
+		}
+		eligible = append(eligible, scored{candidate, preferredScore(task.PreferredLabels, workerLabels)})
+	}
+	sort.SliceStable(eligible, func(i, j int) bool {
+		return eligible[i].score > eligible[j].score
+	})
+
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"status":    "claimed",
+			"worker_id": workerID,
+			"started":   time.Now().Unix(),
+		}},
+		ReturnNew: true,
+	}
+	for _, candidate := range eligible {
+		query := bson.M{"_id": candidate.attempt.ID, "status": "pending"}
+		attempt = &Attempt{}
+		if _, err = b.db.C("attempts").Find(query).Apply(change, attempt); err != nil {
+			if err == mgo.ErrNotFound {
+				err = nil
+				continue
+			}
+			return nil, err
+		}
+		return attempt, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"path"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ExecutionTriggers are the valid values for Execution.Trigger.
+var ExecutionTriggers = map[string]bool{
+	"manual":   true,
+	"schedule": true,
+	"api":      true,
+}
+
+// ExecutionSelector describes which Tasks an Execution runs. TaskIDs takes
+// precedence if set, otherwise TaskNameGlob and Labels are applied together
+// as an AND filter over the Application's non-deleted Tasks, whether active
+// or not.
+type ExecutionSelector struct {
+	// TaskNameGlob is a shell glob, e.g. `daily-*`, matched against Task names.
+	TaskNameGlob string `json:"taskNameGlob,omitempty"`
+
+	// Labels are required Task Labels to match, the same way as a worker's
+	// NodeSelector against a Task's required Labels: values may use shell
+	// glob syntax.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// TaskIDs is an explicit list of Task IDs to run.
+	TaskIDs []string `json:"taskIds,omitempty"`
+}
+
+// Execution groups the Attempts of an ad-hoc or scheduled run of many Tasks
+// together, so that their combined progress can be queried, and the whole
+// batch stopped, as a single unit.
+type Execution struct {
+	// ID is the ID of the Execution.
+	ID bson.ObjectId `bson:"_id"`
+
+	// Account is the ID of the Account owning the Execution.
+	Account bson.ObjectId `bson:"account"`
+
+	// Application is the name of the parent Application.
+	Application string `bson:"application"`
+
+	// Trigger is how the Execution was started: `manual`, `schedule` or `api`.
+	Trigger string `bson:"trigger"`
+
+	// Total is the number of Tasks selected into the Execution.
+	Total int `bson:"total"`
+
+	// Succeeded is the number of Attempts that reached `success`.
+	Succeeded int `bson:"succeeded"`
+
+	// Failed is the number of Attempts that reached `error` with no retry left.
+	Failed int `bson:"failed"`
+
+	// Stopped is the number of Attempts canceled by StopExecution.
+	Stopped int `bson:"stopped"`
+
+	// InProgress is the number of Attempts still `pending`, `claimed` or
+	// `retrying`.
+	InProgress int `bson:"in_progress"`
+
+	// Status summarizes the Execution: `in_progress` while any Attempt
+	// remains pending, claimed or retrying, otherwise `stopped` if any were canceled,
+	// `error` if any failed, or `success`.
+	Status string `bson:"status"`
+
+	// StartTime is the Unix timestamp of when the Execution was created.
+	StartTime int64 `bson:"start_time"`
+
+	// EndTime is the Unix timestamp of when every Attempt reached a terminal
+	// state, or 0 while the Execution is still in progress.
+	EndTime int64 `bson:"end_time,omitempty"`
+}
+
+// NewExecution selects the Tasks matching selector and creates an Execution
+// that immediately runs every one of them as its own Attempt.
+func (b *Base) NewExecution(account bson.ObjectId, application string, selector ExecutionSelector, trigger string) (execution *Execution, err error) {
+	if trigger == "" || !ExecutionTriggers[trigger] {
+		trigger = "manual"
+	}
+
+	tasks, err := b.selectExecutionTasks(account, application, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	execution = &Execution{
+		ID:          bson.NewObjectId(),
+		Account:     account,
+		Application: application,
+		Trigger:     trigger,
+		Total:       len(tasks),
+		InProgress:  len(tasks),
+		Status:      "in_progress",
+		StartTime:   now,
+	}
+	if len(tasks) == 0 {
+		execution.Status = "success"
+		execution.EndTime = now
+	}
+	if err = b.db.C("executions").Insert(execution); err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		if _, err = b.NewAttempt(task, execution.ID); err != nil {
+			return execution, err
+		}
+	}
+	return execution, nil
+}
+
+// selectExecutionTasks resolves an ExecutionSelector against an
+// Application's non-deleted Tasks.
+func (b *Base) selectExecutionTasks(account bson.ObjectId, application string, selector ExecutionSelector) (tasks []*Task, err error) {
+	query := bson.M{
+		"account":     account,
+		"application": application,
+		"deleted":     false,
+	}
+	if len(selector.TaskIDs) > 0 {
+		ids := make([]bson.ObjectId, 0, len(selector.TaskIDs))
+		for _, id := range selector.TaskIDs {
+			if bson.IsObjectIdHex(id) {
+				ids = append(ids, bson.ObjectIdHex(id))
+			}
+		}
+		query["_id"] = bson.M{"$in": ids}
+		err = b.db.C("tasks").Find(query).All(&tasks)
+		return tasks, err
+	}
+
+	var candidates []*Task
+	if err = b.db.C("tasks").Find(query).All(&candidates); err != nil {
+		return nil, err
+	}
+	for _, task := range candidates {
+		if selector.TaskNameGlob != "" {
+			matched, err := path.Match(selector.TaskNameGlob, task.Name)
+			if err != nil || !matched {
+				continue
+			}
+		}
+		if len(selector.Labels) > 0 && !matchLabels(selector.Labels, task.Labels) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// GetExecution returns an Execution given its ID.
+func (b *Base) GetExecution(account bson.ObjectId, executionID bson.ObjectId) (execution *Execution, err error) {
+	execution = &Execution{}
+	err = b.db.C("executions").Find(bson.M{"_id": executionID, "account": account}).One(execution)
+	if err == mgo.ErrNotFound {
+		err = nil
+		execution = nil
+	}
+	return
+}
+
+// StopExecution cancels every still-pending or retrying Attempt belonging to
+// the Execution and recomputes its aggregates.
+func (b *Base) StopExecution(account bson.ObjectId, executionID bson.ObjectId) (execution *Execution, err error) {
+	execution, err = b.GetExecution(account, executionID)
+	if err != nil || execution == nil {
+		return execution, err
+	}
+
+	query := bson.M{
+		"execution_id": executionID,
+		"status":       bson.M{"$in": []string{"pending", "retrying"}},
+	}
+	update := bson.M{"$set": bson.M{"status": "canceled"}}
+	if _, err = b.db.C("attempts").UpdateAll(query, update); err != nil {
+		return execution, err
+	}
+
+	if err = b.recomputeExecution(executionID); err != nil {
+		return execution, err
+	}
+	return b.GetExecution(account, executionID)
+}
+
+// recomputeExecution re-aggregates an Execution's counters from each of its
+// Tasks' latest Attempt status, and marks it terminal once none of them are
+// left `pending`, `claimed` or `retrying`. A retried Task leaves its earlier,
+// errored Attempt documents behind under the same execution_id, so counting
+// is done per task_id's most recent Attempt rather than over every Attempt
+// row, or a retry-then-succeed Task would be double-counted as both failed
+// and succeeded.
+func (b *Base) recomputeExecution(executionID bson.ObjectId) error {
+	var counts []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{"execution_id": executionID, "deleted": false}},
+		{"$sort": bson.M{"_id": -1}},
+		{"$group": bson.M{"_id": "$task_id", "status": bson.M{"$first": "$status"}}},
+		{"$group": bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}},
+	}
+	if err := b.db.C("attempts").Pipe(pipeline).All(&counts); err != nil {
+		return err
+	}
+
+	var succeeded, failed, stopped, inProgress int
+	for _, c := range counts {
+		switch c.Status {
+		case "success":
+			succeeded = c.Count
+		case "error":
+			failed = c.Count
+		case "canceled":
+			stopped = c.Count
+		case "pending", "claimed", "retrying":
+			inProgress += c.Count
+		}
+	}
+
+	execution := &Execution{}
+	if err := b.db.C("executions").FindId(executionID).One(execution); err != nil {
+		return err
+	}
+
+	status := "in_progress"
+	var endTime int64
+	if inProgress == 0 {
+		endTime = execution.EndTime
+		switch {
+		case stopped > 0:
+			status = "stopped"
+		case failed > 0:
+			status = "error"
+		default:
+			status = "success"
+		}
+		if endTime == 0 {
+			endTime = time.Now().Unix()
+		}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"succeeded":   succeeded,
+			"failed":      failed,
+			"stopped":     stopped,
+			"in_progress": inProgress,
+			"status":      status,
+			"end_time":    endTime,
+		},
+	}
+	return b.db.C("executions").UpdateId(executionID, update)
+}
+
+// EnsureExecutionIndex creates mongo indexes for Execution.
+func (b *Base) EnsureExecutionIndex() {
+	index := mgo.Index{
+		Key:        []string{"account", "application", "-start_time"},
+		Background: true,
+	}
+	b.db.C("executions").EnsureIndex(index)
+}
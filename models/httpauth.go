@@ -0,0 +1,11 @@
+package models
+
+// HTTPAuth describes the HTTP authentication to use, if any, when executing
+// a Task.
+type HTTPAuth struct {
+	// User is the username to use for HTTP basic authentication.
+	User string `bson:"user,omitempty" json:"user,omitempty"`
+
+	// Password is the password to use for HTTP basic authentication.
+	Password string `bson:"password,omitempty" json:"password,omitempty"`
+}
@@ -0,0 +1,284 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrInvalidCursor is returned when a ListParams.Cursor fails to decode, so
+// that callers can tell a malformed client input from a database error.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListParams describes the common pagination, sorting and filtering
+// parameters accepted by the list endpoints.
+type ListParams struct {
+	// Filters are the field filters extracted from the query string.
+	Filters map[string]string
+
+	// Sort is the name of the field to sort by, prefixed with `-` for
+	// descending order. Ignored once Cursor is set, which always walks the
+	// collection in `_id` order.
+	Sort string
+
+	// Page is the requested page number, starting at 1. Ignored once Cursor
+	// is set.
+	Page int
+
+	// PageSize is the number of items per page.
+	PageSize int
+
+	// Cursor is an opaque, base64-encoded keyset cursor produced by a
+	// previous ListResult's NextCursor or PrevCursor. It takes precedence
+	// over Page.
+	Cursor string
+
+	// Count, when false, skips the O(N) total-count query; ListResult.Total
+	// and ListResult.Pages are left unset and callers should rely on HasMore
+	// instead. Defaults to true.
+	Count bool
+}
+
+// ListResult is the generic result of a paginated list query.
+type ListResult struct {
+	// List is the list of items for the current page.
+	List interface{} `json:"list"`
+
+	// Total is the total number of items matching the query. Unset when the
+	// query was made with Count: false.
+	Total int `json:"total,omitempty"`
+
+	// Count is the number of items in List.
+	Count int `json:"count"`
+
+	// Page is the current page number. Unset when paginating by Cursor.
+	Page int `json:"page,omitempty"`
+
+	// Pages is the total number of pages. Unset when paginating by Cursor or
+	// with Count: false.
+	Pages int `json:"pages,omitempty"`
+
+	// HasMore is true if there are more items after the current page.
+	HasMore bool `json:"hasMore"`
+
+	// NextCursor, if set, can be passed as ListParams.Cursor to fetch the
+	// page of older items that follows this one.
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// PrevCursor, if set, can be passed as ListParams.Cursor to fetch the
+	// page of newer items that precedes this one.
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// cursor is the decoded form of an opaque ListParams.Cursor: the ID to
+// resume from, and which direction to walk from there.
+type cursor struct {
+	ID  bson.ObjectId `json:"id"`
+	Dir string        `json:"dir"`
+}
+
+// encodeCursor opaquely encodes a keyset cursor.
+func encodeCursor(id bson.ObjectId, dir string) string {
+	data, _ := json.Marshal(cursor{ID: id, Dir: dir})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor, returning
+// ErrInvalidCursor if s is not one.
+func decodeCursor(s string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// getItems runs a sorted query against the given collection and fills lr
+// with the requested page, following either ListParams.Cursor, for
+// efficient keyset pagination on large collections, or ListParams.Page.
+func (b *Base) getItems(collection string, query bson.M, lp ListParams, lr *ListResult) error {
+	pageSize := lp.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	if lp.Cursor != "" {
+		return b.getItemsByCursor(collection, query, lp.Cursor, pageSize, lr)
+	}
+	return b.getItemsByPage(collection, query, lp, pageSize, lr)
+}
+
+// getItemsByPage runs the classic offset-based pagination, honoring
+// ListParams.Count to optionally skip the expensive total count.
+func (b *Base) getItemsByPage(collection string, query bson.M, lp ListParams, pageSize int, lr *ListResult) error {
+	page := lp.Page
+	if page <= 0 {
+		page = 1
+	}
+	sort := lp.Sort
+	if sort == "" {
+		sort = "-_id"
+	}
+
+	q := b.db.C(collection).Find(query).Sort(sort)
+
+	if lp.Count {
+		total, err := q.Count()
+		if err != nil {
+			return err
+		}
+		if err := q.Skip((page - 1) * pageSize).Limit(pageSize).All(lr.List); err != nil {
+			return err
+		}
+		lr.Total = total
+		lr.Pages = (total + pageSize - 1) / pageSize
+		lr.HasMore = page*pageSize < total
+		lr.Page = page
+		lr.Count = sliceLen(lr.List)
+		setPageCursors(lr, sort, page)
+		return nil
+	}
+
+	// Without a total count, over-fetch by one to detect more pages.
+	if err := q.Skip((page - 1) * pageSize).Limit(pageSize + 1).All(lr.List); err != nil {
+		return err
+	}
+	lr.HasMore = sliceLen(lr.List) > pageSize
+	sliceTrim(lr.List, pageSize)
+	lr.Page = page
+	lr.Count = sliceLen(lr.List)
+	setPageCursors(lr, sort, page)
+	return nil
+}
+
+// setPageCursors populates lr.NextCursor/PrevCursor from the current page so
+// that a client can switch to cheaper keyset pagination on a later request.
+// Cursor mode always walks in `_id` order, so this only applies when the
+// page itself was fetched in that same default order.
+func setPageCursors(lr *ListResult, sort string, page int) {
+	if sort != "-_id" || lr.Count == 0 {
+		return
+	}
+	if lr.HasMore {
+		if last, ok := itemID(lr.List, lr.Count-1); ok {
+			lr.NextCursor = encodeCursor(last, "n")
+		}
+	}
+	if page > 1 {
+		if first, ok := itemID(lr.List, 0); ok {
+			lr.PrevCursor = encodeCursor(first, "p")
+		}
+	}
+}
+
+// getItemsByCursor runs keyset pagination on the `_id` index, always
+// walking newest-first, optionally resuming after/before the cursor's ID.
+func (b *Base) getItemsByCursor(collection string, query bson.M, encoded string, pageSize int, lr *ListResult) error {
+	c, err := decodeCursor(encoded)
+	if err != nil {
+		return err
+	}
+
+	cq := bson.M{}
+	for k, v := range query {
+		cq[k] = v
+	}
+
+	sort := "-_id"
+	if c.Dir == "p" {
+		cq["_id"] = bson.M{"$gt": c.ID}
+		sort = "_id"
+	} else {
+		cq["_id"] = bson.M{"$lt": c.ID}
+	}
+
+	if err := b.db.C(collection).Find(cq).Sort(sort).Limit(pageSize + 1).All(lr.List); err != nil {
+		return err
+	}
+
+	hasMore := sliceLen(lr.List) > pageSize
+	sliceTrim(lr.List, pageSize)
+	if c.Dir == "p" {
+		// Results were fetched oldest-first to find the nearest page behind
+		// the cursor; reverse them back to the standard newest-first order.
+		sliceReverse(lr.List)
+	}
+
+	lr.Count = sliceLen(lr.List)
+	if lr.Count > 0 {
+		if first, ok := itemID(lr.List, 0); ok {
+			if c.Dir == "n" || hasMore {
+				lr.PrevCursor = encodeCursor(first, "p")
+			}
+		}
+		if last, ok := itemID(lr.List, lr.Count-1); ok {
+			if c.Dir == "p" || hasMore {
+				lr.NextCursor = encodeCursor(last, "n")
+			}
+		}
+	}
+	if c.Dir == "p" {
+		lr.HasMore = true // there was a cursor behind us, so forward paging remains available
+	} else {
+		lr.HasMore = hasMore
+	}
+	return nil
+}
+
+// sliceLen returns the length of the slice pointed to by a *[]T passed as
+// lr.List.
+func sliceLen(list interface{}) int {
+	v := reflect.ValueOf(list)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Len()
+}
+
+// sliceTrim truncates the slice pointed to by list down to n elements.
+func sliceTrim(list interface{}, n int) {
+	v := reflect.ValueOf(list).Elem()
+	if v.Len() > n {
+		v.Set(v.Slice(0, n))
+	}
+}
+
+// sliceReverse reverses, in place, the slice pointed to by list.
+func sliceReverse(list interface{}) {
+	v := reflect.ValueOf(list).Elem()
+	swap := reflect.Swapper(v.Interface())
+	for i, j := 0, v.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
+
+// itemID returns the `ID bson.ObjectId` field of the i'th element of the
+// slice pointed to by list, every model list item (Task, Attempt, ...)
+// exposing one.
+func itemID(list interface{}, i int) (bson.ObjectId, bool) {
+	v := reflect.ValueOf(list).Elem().Index(i)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	field := v.FieldByName("ID")
+	if !field.IsValid() {
+		return "", false
+	}
+	id, ok := field.Interface().(bson.ObjectId)
+	return id, ok
+}
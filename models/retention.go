@@ -0,0 +1,254 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// RetentionPolicy describes how long a Task's Attempts, and the Task itself
+// once soft-deleted, are kept before being purged for good.
+type RetentionPolicy struct {
+	// MaxAttemptAgeSeconds is the maximum age, in seconds, of any terminal
+	// (`success`, `error` or `canceled`) Attempt before it is purged,
+	// regardless of KeepFailedFor/KeepSuccessFor. Pending, retrying and
+	// claimed Attempts are never purged by this, since Started is unset
+	// until a worker claims one and purging by it would delete a Task's own
+	// not-yet-dispatched Attempt.
+	MaxAttemptAgeSeconds int64 `bson:"max_attempt_age_seconds,omitempty" json:"maxAttemptAgeSeconds,omitempty"`
+
+	// MaxAttemptsPerTask is the maximum number of Attempts kept per Task;
+	// the oldest ones are purged first.
+	MaxAttemptsPerTask int `bson:"max_attempts_per_task,omitempty" json:"maxAttemptsPerTask,omitempty"`
+
+	// KeepFailedFor is the number of seconds failed Attempts are kept.
+	KeepFailedFor int64 `bson:"keep_failed_for,omitempty" json:"keepFailedFor,omitempty"`
+
+	// KeepSuccessFor is the number of seconds successful Attempts are kept.
+	KeepSuccessFor int64 `bson:"keep_success_for,omitempty" json:"keepSuccessFor,omitempty"`
+
+	// PurgeDeletedAfter is the number of seconds a soft-deleted Task, and its
+	// Attempts, are kept before being purged for good.
+	PurgeDeletedAfter int64 `bson:"purge_deleted_after,omitempty" json:"purgeDeletedAfter,omitempty"`
+}
+
+// GetApplicationRetention returns the Application's RetentionPolicy.
+func (b *Base) GetApplicationRetention(account bson.ObjectId, application string) (policy *RetentionPolicy, err error) {
+	app, err := b.GetApplication(account, application)
+	if err != nil || app == nil {
+		return nil, err
+	}
+	return &app.Retention, nil
+}
+
+// PutApplicationRetention sets the Application's RetentionPolicy.
+func (b *Base) PutApplicationRetention(account bson.ObjectId, application string, policy RetentionPolicy) (err error) {
+	query := bson.M{
+		"account": account,
+		"name":    application,
+	}
+	update := bson.M{
+		"$set": bson.M{"retention": policy},
+	}
+	_, err = b.db.C("applications").Upsert(query, update)
+	return
+}
+
+// GetTaskRetention returns the Task's RetentionPolicy override, or nil if the
+// Task inherits its parent Application's policy.
+func (b *Base) GetTaskRetention(account bson.ObjectId, application string, name string) (policy *RetentionPolicy, err error) {
+	task, err := b.GetTask(account, application, name)
+	if err != nil || task == nil {
+		return nil, err
+	}
+	return task.Retention, nil
+}
+
+// PutTaskRetention sets, or clears when policy is nil, the Task's
+// RetentionPolicy override.
+func (b *Base) PutTaskRetention(account bson.ObjectId, application string, name string, policy *RetentionPolicy) (err error) {
+	query := bson.M{
+		"account":     account,
+		"application": application,
+		"name":        name,
+	}
+	update := bson.M{
+		"$set": bson.M{"retention": policy},
+	}
+	_, err = b.db.C("tasks").UpdateAll(query, update)
+	return
+}
+
+// PurgeResult summarizes the outcome of a purge pass.
+type PurgeResult struct {
+	AttemptsRemoved int `json:"attemptsRemoved"`
+	TasksRemoved    int `json:"tasksRemoved"`
+}
+
+// Purge applies the effective RetentionPolicy of an Application, and of
+// every Task that overrides it, deleting expired Attempts and reaping
+// long-soft-deleted Tasks.
+func (b *Base) Purge(account bson.ObjectId, application string) (result PurgeResult, err error) {
+	app, err := b.GetApplication(account, application)
+	if err != nil || app == nil {
+		return result, err
+	}
+
+	var tasks []*Task
+	if err = b.db.C("tasks").Find(bson.M{"account": account, "application": application}).All(&tasks); err != nil {
+		return result, err
+	}
+
+	for _, task := range tasks {
+		policy := app.Retention
+		if task.Retention != nil {
+			policy = *task.Retention
+		}
+		removed, err := b.purgeTaskAttempts(task, policy)
+		if err != nil {
+			return result, err
+		}
+		result.AttemptsRemoved += removed
+	}
+
+	if app.Retention.PurgeDeletedAfter > 0 {
+		before := time.Now().Unix() - app.Retention.PurgeDeletedAfter
+		query := bson.M{
+			"account":     account,
+			"application": application,
+			"deleted":     true,
+			"deleted_at":  bson.M{"$lt": before},
+		}
+		info, err := b.db.C("tasks").RemoveAll(query)
+		if err != nil {
+			return result, err
+		}
+		result.TasksRemoved += info.Removed
+
+		info, err = b.db.C("attempts").RemoveAll(query)
+		if err != nil {
+			return result, err
+		}
+		result.AttemptsRemoved += info.Removed
+	}
+
+	return result, nil
+}
+
+// purgeTaskAttempts deletes the Attempts of task that fall outside policy.
+func (b *Base) purgeTaskAttempts(task *Task, policy RetentionPolicy) (removed int, err error) {
+	now := time.Now().Unix()
+
+	if policy.MaxAttemptAgeSeconds > 0 {
+		info, err := b.db.C("attempts").RemoveAll(bson.M{
+			"task_id": task.ID,
+			"status":  bson.M{"$in": []string{"success", "error", "canceled"}},
+			"started": bson.M{"$lt": now - policy.MaxAttemptAgeSeconds},
+		})
+		if err != nil {
+			return removed, err
+		}
+		removed += info.Removed
+	}
+	if policy.KeepFailedFor > 0 {
+		info, err := b.db.C("attempts").RemoveAll(bson.M{
+			"task_id": task.ID,
+			"status":  "error",
+			"started": bson.M{"$lt": now - policy.KeepFailedFor},
+		})
+		if err != nil {
+			return removed, err
+		}
+		removed += info.Removed
+	}
+	if policy.KeepSuccessFor > 0 {
+		info, err := b.db.C("attempts").RemoveAll(bson.M{
+			"task_id": task.ID,
+			"status":  "success",
+			"started": bson.M{"$lt": now - policy.KeepSuccessFor},
+		})
+		if err != nil {
+			return removed, err
+		}
+		removed += info.Removed
+	}
+	if policy.MaxAttemptsPerTask > 0 {
+		var stale []struct {
+			ID bson.ObjectId `bson:"_id"`
+		}
+		err := b.db.C("attempts").Find(bson.M{"task_id": task.ID}).
+			Sort("-_id").Skip(policy.MaxAttemptsPerTask).Select(bson.M{"_id": 1}).All(&stale)
+		if err != nil {
+			return removed, err
+		}
+		if len(stale) > 0 {
+			ids := make([]bson.ObjectId, len(stale))
+			for i, s := range stale {
+				ids[i] = s.ID
+			}
+			info, err := b.db.C("attempts").RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+			if err != nil {
+				return removed, err
+			}
+			removed += info.Removed
+		}
+	}
+	return removed, nil
+}
+
+// RetentionRunner periodically purges expired Attempts and Tasks across
+// every Application according to its RetentionPolicy.
+type RetentionRunner struct {
+	db       func() *mgo.Database
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// NewRetentionRunner creates a RetentionRunner that opens a fresh database
+// handle via db for every purge pass, at the given interval.
+func NewRetentionRunner(db func() *mgo.Database, interval time.Duration) *RetentionRunner {
+	return &RetentionRunner{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Run blocks, purging every Application at the configured interval, until
+// Stop is called.
+func (rr *RetentionRunner) Run() {
+	ticker := time.NewTicker(rr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rr.purgeAll()
+		case <-rr.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates the runner's purge loop.
+func (rr *RetentionRunner) Stop() {
+	close(rr.stop)
+}
+
+func (rr *RetentionRunner) purgeAll() {
+	db := rr.db()
+	defer db.Session.Close()
+	b := NewBase(db)
+
+	var apps []*Application
+	if err := db.C("applications").Find(bson.M{"deleted": false}).All(&apps); err != nil {
+		fmt.Printf("Error listing applications for retention purge: %s\n", err)
+		return
+	}
+	for _, app := range apps {
+		if _, err := b.Purge(app.Account, app.Name); err != nil {
+			fmt.Printf("Error purging %s/%s: %s\n", app.Account.Hex(), app.Name, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrMaxAttemptsReached is returned by Retry.NextAttempt once the retry
+// strategy has been exhausted.
+var ErrMaxAttemptsReached = errors.New("max attempts reached")
+
+// Retry describes the retry strategy to apply when attempts of a Task fail.
+type Retry struct {
+	// Attempts is the number of attempts already performed in the current
+	// retry cycle.
+	Attempts int `bson:"attempts" json:"attempts,omitempty"`
+
+	// MaxAttempts is the maximum number of attempts before giving up.
+	MaxAttempts int `bson:"max_attempts" json:"maxAttempts,omitempty"`
+
+	// Factor is the exponential backoff factor applied between attempts.
+	Factor float64 `bson:"factor" json:"factor,omitempty"`
+
+	// Min is the minimum number of seconds to wait before the next attempt.
+	Min int `bson:"min" json:"min,omitempty"`
+
+	// Max is the maximum number of seconds to wait before the next attempt.
+	Max int `bson:"max" json:"max,omitempty"`
+}
+
+// NextAttempt returns the Unix timestamp, in nanoseconds, of the next
+// attempt given the current time, or ErrMaxAttemptsReached once MaxAttempts
+// has been reached.
+func (r *Retry) NextAttempt(now int64) (int64, error) {
+	if r.Attempts >= r.MaxAttempts {
+		return 0, ErrMaxAttemptsReached
+	}
+	wait := float64(r.Min) * math.Pow(r.Factor, float64(r.Attempts))
+	if max := float64(r.Max); wait > max {
+		wait = max
+	}
+	return now + int64(wait*float64(time.Second)), nil
+}
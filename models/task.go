@@ -35,6 +35,16 @@ type Task struct {
 	// Queue is the name of the parent Queue.
 	Queue string `bson:"queue"`
 
+	// Labels are the required worker capability selectors for this Task's
+	// Attempts, matched against a worker's NodeSelector. Values may use shell
+	// glob syntax, e.g. `region=eu-*`.
+	Labels map[string]string `bson:"labels,omitempty"`
+
+	// PreferredLabels are optional worker capability selectors: a worker
+	// that doesn't satisfy them is still eligible, but workers that do are
+	// preferred.
+	PreferredLabels map[string]string `bson:"preferred_labels,omitempty"`
+
 	// URL is the URL that the worker with requests.
 	URL string `bson:"url"`
 
@@ -80,8 +90,22 @@ type Task struct {
 	// Retry is the retry strategy parameters in case of errors.
 	Retry Retry `bson:"retry"`
 
+	// Callbacks are the URLs to POST to when the Task reaches a terminal state.
+	Callbacks Callbacks `bson:"callbacks,omitempty"`
+
+	// Retention overrides the parent Application's RetentionPolicy for this
+	// Task's own Attempts, if set.
+	Retention *RetentionPolicy `bson:"retention,omitempty"`
+
+	// CircuitBreaker auto-disables the Task once it sustains a high enough
+	// error rate, if configured.
+	CircuitBreaker CircuitBreaker `bson:"circuit_breaker,omitempty"`
+
 	// Deleted
 	Deleted bool `bson:"deleted"`
+
+	// DeletedAt is the timestamp at which Deleted was set to true.
+	DeletedAt int64 `bson:"deleted_at,omitempty"`
 }
 
 // ErrorRate is the error rate of the task from 0 to 100 percent.
@@ -101,7 +125,7 @@ func nextRun(schedule string) (int64, error) {
 }
 
 // NewTask creates a new Task.
-func (b *Base) NewTask(account bson.ObjectId, application string, name string, queue string, URL string, auth HTTPAuth, method string, headers map[string]string, payload string, schedule string, retry Retry, active bool) (task *Task, err error) {
+func (b *Base) NewTask(account bson.ObjectId, application string, name string, queue string, URL string, auth HTTPAuth, method string, headers map[string]string, payload string, schedule string, retry Retry, callbacks Callbacks, labels map[string]string, preferredLabels map[string]string, active bool) (task *Task, err error) {
 	taskID := bson.NewObjectId()
 	// If no name is provided we use the Task ID
 	if name == "" {
@@ -145,37 +169,43 @@ func (b *Base) NewTask(account bson.ObjectId, application string, name string, q
 	}
 	// Create a new `Task` and store it.
 	task = &Task{
-		ID:          taskID,
-		Account:     account,
-		Application: application,
-		Queue:       queue,
-		Name:        name,
-		URL:         URL,
-		HTTPAuth:    auth,
-		Method:      method,
-		Headers:     headers,
-		Payload:     payload,
-		At:          at,
-		Status:      "pending",
-		Active:      at > 0 && active,
-		Schedule:    schedule,
-		Retry:       retry,
+		ID:              taskID,
+		Account:         account,
+		Application:     application,
+		Queue:           queue,
+		Name:            name,
+		URL:             URL,
+		HTTPAuth:        auth,
+		Method:          method,
+		Headers:         headers,
+		Payload:         payload,
+		At:              at,
+		Status:          "pending",
+		Active:          at > 0 && active,
+		Schedule:        schedule,
+		Retry:           retry,
+		Callbacks:       callbacks,
+		Labels:          labels,
+		PreferredLabels: preferredLabels,
 	}
 	err = b.db.C("tasks").Insert(task)
 	if mgo.IsDup(err) {
 		change := mgo.Change{
 			Update: bson.M{
 				"$set": bson.M{
-					"url":      task.URL,
-					"method":   task.Method,
-					"headers":  task.Headers,
-					"payload":  task.Payload,
-					"at":       task.At,
-					"active":   task.At > 0 && active,
-					"schedule": task.Schedule,
-					"retry":    task.Retry,
-					"auth":     task.HTTPAuth,
-					"deleted":  false,
+					"url":              task.URL,
+					"method":           task.Method,
+					"headers":          task.Headers,
+					"payload":          task.Payload,
+					"at":               task.At,
+					"active":           task.At > 0 && active,
+					"schedule":         task.Schedule,
+					"retry":            task.Retry,
+					"auth":             task.HTTPAuth,
+					"callbacks":        task.Callbacks,
+					"labels":           task.Labels,
+					"preferred_labels": task.PreferredLabels,
+					"deleted":          false,
 				},
 			},
 			ReturnNew: true,
@@ -190,11 +220,11 @@ func (b *Base) NewTask(account bson.ObjectId, application string, name string, q
 			var deleted bool
 			deleted, err = b.DeletePendingAttempts(task.ID)
 			if deleted {
-				_, err = b.NewAttempt(task)
+				_, err = b.NewAttempt(task, "")
 			}
 		}
 	} else if err == nil {
-		_, err = b.NewAttempt(task)
+		_, err = b.NewAttempt(task, "")
 	}
 	return
 }
@@ -236,7 +266,8 @@ func (b *Base) DeleteTask(account bson.ObjectId, application string, name string
 	}
 	update := bson.M{
 		"$set": bson.M{
-			"deleted": true,
+			"deleted":    true,
+			"deleted_at": time.Now().Unix(),
 		},
 	}
 	if _, err = b.db.C("tasks").UpdateAll(query, update); err == nil {
@@ -258,7 +289,8 @@ func (b *Base) DeleteTasks(account bson.ObjectId, application string) (err error
 	}
 	update := bson.M{
 		"$set": bson.M{
-			"deleted": true,
+			"deleted":    true,
+			"deleted_at": time.Now().Unix(),
 		},
 	}
 	if _, err = b.db.C("tasks").UpdateAll(query, update); err == nil {
@@ -296,6 +328,8 @@ func (b *Base) NextAttemptForTask(taskID bson.ObjectId, status string) (attempt
 	if err = b.db.C("tasks").FindId(taskID).One(task); err != nil {
 		return nil, err
 	}
+	previousStatus := task.Status
+
 	var at int64
 	if task.Active && task.Schedule != "" {
 		at, err = nextRun(task.Schedule)
@@ -305,38 +339,106 @@ func (b *Base) NextAttemptForTask(taskID bson.ObjectId, status string) (attempt
 
 	errors := 0
 	retryAttempts := 1
+	retryExhausted := false
 	if status == "error" {
 		errors = 1
 
 		at, err = task.Retry.NextAttempt(now.UnixNano())
 		if err == nil {
 			status = "retrying"
+		} else {
+			retryExhausted = true
 		}
 	} else if status == "success" {
 		retryAttempts = -task.Retry.Attempts
 	}
 
+	tripped, err := b.recordCircuitBreaker(task, now, errors == 1)
+	if err != nil {
+		return nil, err
+	}
+	trips := 0
+	if tripped && task.CircuitBreaker.State(now.Unix()) == "closed" {
+		trips = 1
+	}
+
+	set := bson.M{
+		"status":         status,
+		"updated":        now.Unix(),
+		"executed":       now.Unix(),
+		"last_" + status: now.Unix(),
+		"at":             at,
+		"active":         at > 0 && trips == 0,
+	}
+	inc := bson.M{
+		"executions":     1,
+		"errors":         errors,
+		"retry.attempts": retryAttempts,
+	}
+	if trips > 0 {
+		set["circuit_breaker.tripped_at"] = now.Unix()
+		inc["circuit_breaker.trip_count"] = trips
+	}
+
 	change := mgo.Change{
 		Update: bson.M{
-			"$set": bson.M{
-				"status":         status,
-				"updated":        now.Unix(),
-				"executed":       now.Unix(),
-				"last_" + status: now.Unix(),
-				"at":             at,
-				"active":         at > 0,
-			},
-			"$inc": bson.M{
-				"executions":     1,
-				"errors":         errors,
-				"retry.attempts": retryAttempts,
-			},
+			"$set": set,
+			"$inc": inc,
 		},
 		ReturnNew: true,
 	}
 	_, err = b.db.C("tasks").FindId(taskID).Apply(change, task)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Callbacks.OnStatusChange != "" || task.Callbacks.OnSuccess != "" || task.Callbacks.OnError != "" {
+		lastAttempt := &Attempt{}
+		var summary string
+		if err := b.db.C("attempts").Find(bson.M{"task_id": taskID, "internal": bson.M{"$ne": true}}).Sort("-_id").One(lastAttempt); err == nil {
+			summary = responseSummary(lastAttempt)
+		}
+
+		if task.Status != previousStatus && task.Callbacks.OnStatusChange != "" {
+			if _, err := b.enqueueCallback(task, task.Callbacks.OnStatusChange, summary); err != nil {
+				fmt.Printf("Error enqueueing on_status_change callback for task %s: %s\n", task.ID.Hex(), err)
+			}
+		}
+		if task.Status == "success" && task.Callbacks.OnSuccess != "" {
+			if _, err := b.enqueueCallback(task, task.Callbacks.OnSuccess, summary); err != nil {
+				fmt.Printf("Error enqueueing on_success callback for task %s: %s\n", task.ID.Hex(), err)
+			}
+		}
+		if retryExhausted && task.Callbacks.OnError != "" {
+			if _, err := b.enqueueCallback(task, task.Callbacks.OnError, summary); err != nil {
+				fmt.Printf("Error enqueueing on_error callback for task %s: %s\n", task.ID.Hex(), err)
+			}
+		}
+	}
+
+	// The Attempt that just terminated carries the ExecutionID, if any, that
+	// this whole batch run is tracked under; propagate it to the retry so the
+	// Execution's aggregates keep counting it, and recompute those aggregates
+	// once that retry, if any, has been enqueued. The Task's next scheduled
+	// run is not part of the Execution.
+	var executionID bson.ObjectId
+	last := &Attempt{}
+	err = b.db.C("attempts").Find(bson.M{"task_id": taskID, "internal": bson.M{"$ne": true}}).Sort("-_id").One(last)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	err = nil
+	executionID = last.ExecutionID
+	nextExecutionID := executionID
+	if task.Status != "retrying" {
+		nextExecutionID = ""
+	}
+
 	if task.Active && task.At != 0 && !task.Deleted {
-		attempt, err = b.NewAttempt(task)
+		attempt, err = b.NewAttempt(task, nextExecutionID)
+	}
+	if err == nil && executionID != "" {
+		err = b.recomputeExecution(executionID)
 	}
 	return
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Worker is a registered agent able to execute Tasks' Attempts, advertising
+// the capabilities it supports via Labels, its "NodeSelector".
+type Worker struct {
+	// ID is the ID of the Worker.
+	ID bson.ObjectId `bson:"_id"`
+
+	// Account is the ID of the Account owning the Worker.
+	Account bson.ObjectId `bson:"account"`
+
+	// Queue is the name of the queue the Worker pulls Attempts from.
+	Queue string `bson:"queue"`
+
+	// Labels are the capabilities advertised by the Worker, matched against
+	// a Task's required and preferred Labels.
+	Labels map[string]string `bson:"labels,omitempty"`
+
+	// LastSeen is the Unix timestamp of the Worker's last registration.
+	LastSeen int64 `bson:"last_seen"`
+}
+
+// RegisterWorker creates, or refreshes, a Worker's registration.
+func (b *Base) RegisterWorker(account bson.ObjectId, queue string, labels map[string]string) (worker *Worker, err error) {
+	if queue == "" {
+		queue = "default"
+	}
+	worker = &Worker{
+		ID:       bson.NewObjectId(),
+		Account:  account,
+		Queue:    queue,
+		Labels:   labels,
+		LastSeen: time.Now().Unix(),
+	}
+	err = b.db.C("workers").Insert(worker)
+	return
+}
+
+// GetWorkerByID returns a Worker given its ID.
+func (b *Base) GetWorkerByID(account bson.ObjectId, workerID bson.ObjectId) (worker *Worker, err error) {
+	query := bson.M{
+		"_id":     workerID,
+		"account": account,
+	}
+	worker = &Worker{}
+	err = b.db.C("workers").Find(query).One(worker)
+	if err == mgo.ErrNotFound {
+		err = nil
+		worker = nil
+	}
+	return
+}
+
+// EnsureWorkerIndex creates mongo indexes for Worker.
+func (b *Base) EnsureWorkerIndex() {
+	index := mgo.Index{
+		Key:        []string{"account", "queue"},
+		Background: true,
+	}
+	b.db.C("workers").EnsureIndex(index)
+}
@@ -0,0 +1,30 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// Account is used for the Rest API.
+type Account struct {
+	// ID is the Account ID.
+	ID string `json:"id"`
+
+	// APIKey is the secret key used to authenticate requests for this Account.
+	APIKey string `json:"apiKey"`
+}
+
+// PostAccount ...
+func PostAccount(w rest.ResponseWriter, r *rest.Request) {
+	b := GetBase(r)
+	account, err := b.NewAccount()
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(&Account{
+		ID:     account.ID.Hex(),
+		APIKey: account.APIKey,
+	})
+}
@@ -0,0 +1,41 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// Application is used for the Rest API.
+type Application struct {
+	// ID is the Application ID.
+	ID string `json:"id"`
+
+	// Account is the ID of the Account owning the Application.
+	Account string `json:"account"`
+
+	// Name is the application's name.
+	Name string `json:"name"`
+}
+
+// PutApplication ...
+func PutApplication(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	applicationName := r.PathParam("application")
+
+	b := GetBase(r)
+	application, err := b.NewApplication(accountID, applicationName)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(&Application{
+		ID:      application.ID.Hex(),
+		Account: application.Account.Hex(),
+		Name:    application.Name,
+	})
+}
@@ -0,0 +1,194 @@
+package restapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/sebest/hooky/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var errInvalidAttemptID = errors.New("invalid attempt id")
+
+// Attempt is used for the Rest API.
+type Attempt struct {
+	// ID is the Attempt ID.
+	ID string `json:"id"`
+
+	// Created is the date when the Attempt was created.
+	Created string `json:"created"`
+
+	// Started is the date when the request was sent, if any.
+	Started string `json:"started,omitempty"`
+
+	// Finished is the date when the response, or error, was received, if any.
+	Finished string `json:"finished,omitempty"`
+
+	// DurationMs is the duration of the request in milliseconds.
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	// HTTPStatus is the HTTP status code of the response if any.
+	HTTPStatus int `json:"httpStatus,omitempty"`
+
+	// ResponseHeaders are the HTTP headers of the response if any.
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+
+	// ResponseBody is the response body, truncated to models.MaxInlineResponseBodySize.
+	ResponseBody string `json:"responseBody,omitempty"`
+
+	// ResponseBodySize is the size in bytes of the full, untruncated response body.
+	ResponseBodySize int `json:"responseBodySize,omitempty"`
+
+	// ResponseBodyTruncated is true when ResponseBody was truncated and the
+	// full body must be fetched from the `/body` endpoint.
+	ResponseBodyTruncated bool `json:"responseBodyTruncated"`
+
+	// Error is the error message if the request could not be completed.
+	Error string `json:"error,omitempty"`
+
+	// RetryNumber is the retry number of this Attempt, 0 for the first attempt.
+	RetryNumber int `json:"retryNumber"`
+
+	// NextRetryAt is the date of the next scheduled attempt, if any.
+	NextRetryAt string `json:"nextRetryAt,omitempty"`
+
+	// Status is either `pending`, `claimed`, `retrying`, `canceled`, `success`
+	// or `error`.
+	Status string `json:"status"`
+}
+
+// NewAttemptFromModel returns an Attempt object for use with the Rest API
+// from an Attempt model.
+func NewAttemptFromModel(attempt *models.Attempt) *Attempt {
+	return &Attempt{
+		ID:                    attempt.ID.Hex(),
+		Created:               attempt.ID.Time().UTC().Format(time.RFC3339),
+		Started:               UnixToRFC3339(attempt.Started),
+		Finished:              UnixToRFC3339(attempt.Finished),
+		DurationMs:            attempt.DurationMs,
+		HTTPStatus:            attempt.HTTPStatus,
+		ResponseHeaders:       attempt.ResponseHeaders,
+		ResponseBody:          string(attempt.ResponseBody),
+		ResponseBodySize:      attempt.ResponseBodySize,
+		ResponseBodyTruncated: attempt.ResponseBodyTruncated,
+		Error:                 attempt.Error,
+		RetryNumber:           attempt.RetryNumber,
+		NextRetryAt:           UnixToRFC3339(attempt.NextRetryAt),
+		Status:                attempt.Status,
+	}
+}
+
+func pathAttemptID(r *rest.Request) (bson.ObjectId, error) {
+	id := r.PathParam("attempt")
+	if !bson.IsObjectIdHex(id) {
+		return "", errInvalidAttemptID
+	}
+	return bson.ObjectIdHex(id), nil
+}
+
+// GetAttempts ...
+func GetAttempts(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, taskName, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	lp := parseListQuery(r)
+	var attempts []*models.Attempt
+	lr := &models.ListResult{
+		List: &attempts,
+	}
+
+	if err := b.GetAttempts(accountID, applicationName, taskName, lp, lr); err != nil {
+		if err == models.ErrInvalidCursor {
+			rest.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ra := make([]*Attempt, len(attempts))
+	for idx, attempt := range attempts {
+		ra[idx] = NewAttemptFromModel(attempt)
+	}
+	writeListHeaders(w, r, lp, lr)
+	w.WriteJson(models.ListResult{
+		List:       ra,
+		HasMore:    lr.HasMore,
+		Total:      lr.Total,
+		Count:      lr.Count,
+		Page:       lr.Page,
+		Pages:      lr.Pages,
+		NextCursor: lr.NextCursor,
+		PrevCursor: lr.PrevCursor,
+	})
+}
+
+// GetAttempt ...
+func GetAttempt(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	attemptID, err := pathAttemptID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	attempt, err := b.GetAttempt(accountID, attemptID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attempt == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(NewAttemptFromModel(attempt))
+}
+
+// GetAttemptBody streams the full, untruncated response body of an Attempt.
+func GetAttemptBody(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	attemptID, err := pathAttemptID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	attempt, err := b.GetAttempt(accountID, attemptID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attempt == nil {
+		rest.NotFound(w, r)
+		return
+	}
+
+	body, size, err := b.OpenResponseBody(attempt)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	rw := w.(http.ResponseWriter)
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	io.Copy(rw, body)
+}
@@ -0,0 +1,39 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// AuthBasicMiddleware implements HTTP Basic authentication. Unlike
+// rest.AuthBasicMiddleware, it gives the Authenticator and Authorizator
+// access to the current *rest.Request so they can look up per-account
+// state via GetBase.
+type AuthBasicMiddleware struct {
+	// Realm is the HTTP authentication realm.
+	Realm string
+
+	// Authenticator validates the account and key found in the request.
+	Authenticator func(account string, key string, r *rest.Request) bool
+
+	// Authorizator checks that the authenticated account may access the request.
+	Authorizator func(account string, r *rest.Request) bool
+}
+
+// MiddlewareFunc implements rest.Middleware.
+func (mw *AuthBasicMiddleware) MiddlewareFunc(next rest.HandlerFunc) rest.HandlerFunc {
+	return func(w rest.ResponseWriter, r *rest.Request) {
+		account, key, ok := r.BasicAuth()
+		if !ok || !mw.Authenticator(account, key, r) {
+			w.Header().Set("WWW-Authenticate", "Basic realm="+mw.Realm)
+			rest.Error(w, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+		if !mw.Authorizator(account, r) {
+			rest.Error(w, "Not Authorized", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,51 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/sebest/hooky/models"
+)
+
+// GetTaskCircuitBreaker ...
+func GetTaskCircuitBreaker(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, taskName, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	task, err := b.GetTask(accountID, applicationName, taskName)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if task == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(&task.CircuitBreaker)
+}
+
+// PutTaskCircuitBreaker ...
+func PutTaskCircuitBreaker(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, taskName, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	cb := &models.CircuitBreaker{}
+	if err := r.DecodeJsonPayload(cb); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	if err := b.PutTaskCircuitBreaker(accountID, applicationName, taskName, *cb); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(cb)
+}
@@ -0,0 +1,160 @@
+package restapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/sebest/hooky/models"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var errInvalidExecutionID = errors.New("invalid execution id")
+
+// Execution is used for the Rest API.
+type Execution struct {
+	// ID is the Execution ID.
+	ID string `json:"id"`
+
+	// Application is the name of the parent Application.
+	Application string `json:"application"`
+
+	// Trigger is how the Execution was started: `manual`, `schedule` or `api`.
+	Trigger string `json:"trigger"`
+
+	// Total is the number of Tasks selected into the Execution.
+	Total int `json:"total"`
+
+	// Succeeded is the number of Attempts that reached `success`.
+	Succeeded int `json:"succeeded"`
+
+	// Failed is the number of Attempts that reached `error` with no retry left.
+	Failed int `json:"failed"`
+
+	// InProgress is the number of Attempts still `pending`, `claimed` or
+	// `retrying`.
+	InProgress int `json:"inProgress"`
+
+	// Stopped is the number of Attempts canceled by a `stop` call.
+	Stopped int `json:"stopped"`
+
+	// Status summarizes the Execution: `in_progress`, `stopped`, `error` or
+	// `success`.
+	Status string `json:"status"`
+
+	// StartTime is the date the Execution was created.
+	StartTime string `json:"startTime,omitempty"`
+
+	// EndTime is the date every Attempt reached a terminal state, if it has.
+	EndTime string `json:"endTime,omitempty"`
+}
+
+// NewExecutionFromModel returns an Execution object for use with the Rest
+// API from an Execution model.
+func NewExecutionFromModel(execution *models.Execution) *Execution {
+	return &Execution{
+		ID:          execution.ID.Hex(),
+		Application: execution.Application,
+		Trigger:     execution.Trigger,
+		Total:       execution.Total,
+		Succeeded:   execution.Succeeded,
+		Failed:      execution.Failed,
+		InProgress:  execution.InProgress,
+		Stopped:     execution.Stopped,
+		Status:      execution.Status,
+		StartTime:   UnixToRFC3339(execution.StartTime),
+		EndTime:     UnixToRFC3339(execution.EndTime),
+	}
+}
+
+// postExecutionRequest is the body of a PostExecution request: a Task
+// selector plus an optional trigger.
+type postExecutionRequest struct {
+	models.ExecutionSelector
+	Trigger string `json:"trigger,omitempty"`
+}
+
+func pathExecutionID(r *rest.Request) (bson.ObjectId, error) {
+	id := r.PathParam("execution")
+	if !bson.IsObjectIdHex(id) {
+		return "", errInvalidExecutionID
+	}
+	return bson.ObjectIdHex(id), nil
+}
+
+// PostExecution selects Tasks per the request body and runs every one of
+// them immediately as a new Execution.
+func PostExecution(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, _, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	req := &postExecutionRequest{}
+	if err := r.DecodeJsonPayload(req); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	execution, err := b.NewExecution(accountID, applicationName, req.ExecutionSelector, req.Trigger)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(NewExecutionFromModel(execution))
+}
+
+// GetExecution ...
+func GetExecution(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	executionID, err := pathExecutionID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	execution, err := b.GetExecution(accountID, executionID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if execution == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(NewExecutionFromModel(execution))
+}
+
+// StopExecution cancels every still-pending or retrying Attempt belonging
+// to the Execution.
+func StopExecution(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	executionID, err := pathExecutionID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	execution, err := b.StopExecution(accountID, executionID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if execution == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(NewExecutionFromModel(execution))
+}
@@ -0,0 +1,95 @@
+package restapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/sebest/hooky/models"
+)
+
+// parseListQuery extracts the common pagination, sort and filter parameters
+// from the request's query string.
+func parseListQuery(r *rest.Request) models.ListParams {
+	lp := models.ListParams{
+		Filters: map[string]string{},
+		Count:   true,
+	}
+	query := r.URL.Query()
+	if page, err := strconv.Atoi(query.Get("page")); err == nil {
+		lp.Page = page
+	}
+	if pageSize, err := strconv.Atoi(query.Get("page_size")); err == nil {
+		lp.PageSize = pageSize
+	}
+	lp.Sort = query.Get("sort")
+	lp.Cursor = query.Get("cursor")
+	if count, err := strconv.ParseBool(query.Get("count")); err == nil {
+		lp.Count = count
+	}
+	for key, values := range query {
+		switch key {
+		case "page", "page_size", "sort", "cursor", "count":
+			continue
+		}
+		if len(values) > 0 {
+			lp.Filters[key] = values[0]
+		}
+	}
+	return lp
+}
+
+// writeListHeaders sets the `X-Total-Count`, `X-Has-More` and RFC 5988
+// `Link` headers describing a paginated ListResult, reusing the request's
+// own query string so filters and sort carry over from page to page.
+func writeListHeaders(w rest.ResponseWriter, r *rest.Request, lp models.ListParams, lr *models.ListResult) {
+	rw := w.(http.ResponseWriter)
+	if lp.Count {
+		rw.Header().Set("X-Total-Count", strconv.Itoa(lr.Total))
+	}
+	rw.Header().Set("X-Has-More", strconv.FormatBool(lr.HasMore))
+
+	var links []string
+	add := func(rel string, params map[string]string) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, listLink(r, params), rel))
+	}
+	if lr.NextCursor != "" {
+		add("next", map[string]string{"cursor": lr.NextCursor, "page": ""})
+	} else if lp.Cursor == "" && lr.HasMore {
+		add("next", map[string]string{"page": strconv.Itoa(lr.Page + 1)})
+	}
+	if lr.PrevCursor != "" {
+		add("prev", map[string]string{"cursor": lr.PrevCursor, "page": ""})
+	} else if lp.Cursor == "" && lr.Page > 1 {
+		add("prev", map[string]string{"page": strconv.Itoa(lr.Page - 1)})
+	}
+	if lp.Cursor == "" {
+		add("first", map[string]string{"page": "1"})
+		if lp.Count {
+			add("last", map[string]string{"page": strconv.Itoa(lr.Pages)})
+		}
+	} else {
+		add("first", map[string]string{"cursor": ""})
+	}
+	if len(links) > 0 {
+		rw.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// listLink returns the request's URL with its query string patched by
+// params, deleting a key when its value is empty.
+func listLink(r *rest.Request, params map[string]string) string {
+	u := *r.URL
+	q := u.Query()
+	for key, value := range params {
+		if value == "" {
+			q.Del(key)
+		} else {
+			q.Set(key, value)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
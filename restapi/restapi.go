@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/sebest/hooky/models"
@@ -9,6 +10,14 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
+// RetentionInterval is how often the RetentionRunner purges expired
+// Attempts and Tasks.
+const RetentionInterval = 1 * time.Hour
+
+// CircuitBreakerInterval is how often the CircuitBreakerRunner re-arms
+// cooled-down breakers.
+const CircuitBreakerInterval = 1 * time.Minute
+
 func GetAccount(r *rest.Request) *bson.ObjectId {
 	if rv, ok := r.Env["REMOTE_USER"]; ok {
 		id := bson.ObjectIdHex(rv.(string))
@@ -64,6 +73,9 @@ func New(s *store.Store) (*rest.Api, error) {
 	models.NewBase(db).EnsureIndex()
 	db.Session.Close()
 
+	go models.NewRetentionRunner(s.DB, RetentionInterval).Run()
+	go models.NewCircuitBreakerRunner(s.DB, CircuitBreakerInterval).Run()
+
 	api := rest.NewApi()
 	api.Use(rest.DefaultDevStack...)
 	api.Use(&BaseMiddleware{
@@ -88,6 +100,23 @@ func New(s *store.Store) (*rest.Api, error) {
 		rest.Put("/accounts/:account/applications/:application/tasks/:task", PutTask),
 		rest.Get("/accounts/:account/applications/:application/tasks/:task", GetTask),
 		rest.Delete("/accounts/:account/applications/:application/tasks/:task", DeleteTask),
+		rest.Get("/accounts/:account/applications/:application/attempts", GetAttempts),
+		rest.Get("/accounts/:account/applications/:application/tasks/:task/attempts", GetAttempts),
+		rest.Get("/accounts/:account/attempts/:attempt", GetAttempt),
+		rest.Get("/accounts/:account/attempts/:attempt/body", GetAttemptBody),
+		rest.Get("/accounts/:account/applications/:application/retention", GetApplicationRetention),
+		rest.Put("/accounts/:account/applications/:application/retention", PutApplicationRetention),
+		rest.Post("/accounts/:account/applications/:application/retention/purge", PurgeApplication),
+		rest.Get("/accounts/:account/applications/:application/tasks/:task/retention", GetTaskRetention),
+		rest.Put("/accounts/:account/applications/:application/tasks/:task/retention", PutTaskRetention),
+		rest.Post("/accounts/:account/workers", PostWorker),
+		rest.Post("/accounts/:account/workers/:worker/claim", ClaimAttempt),
+		rest.Post("/accounts/:account/workers/:worker/attempts/:attempt", CompleteAttempt),
+		rest.Get("/accounts/:account/applications/:application/tasks/:task/circuit-breaker", GetTaskCircuitBreaker),
+		rest.Put("/accounts/:account/applications/:application/tasks/:task/circuit-breaker", PutTaskCircuitBreaker),
+		rest.Post("/accounts/:account/applications/:application/executions", PostExecution),
+		rest.Get("/accounts/:account/applications/:application/executions/:execution", GetExecution),
+		rest.Post("/accounts/:account/applications/:application/executions/:execution/stop", StopExecution),
 	)
 	if err != nil {
 		return nil, err
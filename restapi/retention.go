@@ -0,0 +1,112 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/sebest/hooky/models"
+)
+
+// GetApplicationRetention ...
+func GetApplicationRetention(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, _, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	policy, err := b.GetApplicationRetention(accountID, applicationName)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if policy == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(policy)
+}
+
+// PutApplicationRetention ...
+func PutApplicationRetention(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, _, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	policy := &models.RetentionPolicy{}
+	if err := r.DecodeJsonPayload(policy); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	if err := b.PutApplicationRetention(accountID, applicationName, *policy); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(policy)
+}
+
+// GetTaskRetention ...
+func GetTaskRetention(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, taskName, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	policy, err := b.GetTaskRetention(accountID, applicationName, taskName)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if policy == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(policy)
+}
+
+// PutTaskRetention ...
+func PutTaskRetention(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, taskName, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	policy := &models.RetentionPolicy{}
+	if err := r.DecodeJsonPayload(policy); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	if err := b.PutTaskRetention(accountID, applicationName, taskName, policy); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(policy)
+}
+
+// PurgeApplication triggers an on-demand purge of an Application's expired
+// Attempts and Tasks.
+func PurgeApplication(w rest.ResponseWriter, r *rest.Request) {
+	accountID, applicationName, _, err := taskParams(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	result, err := b.Purge(accountID, applicationName)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(result)
+}
@@ -29,6 +29,16 @@ type Task struct {
 	// Queue is the name of the parent Queue.
 	Queue string `json:"queue"`
 
+	// Labels are the required worker capability selectors for this Task,
+	// matched against a worker's NodeSelector. Values may use shell glob
+	// syntax, e.g. `region=eu-*`.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// PreferredLabels are optional worker capability selectors: a worker
+	// that doesn't satisfy them is still eligible, but workers that do are
+	// preferred.
+	PreferredLabels map[string]string `json:"preferredLabels,omitempty"`
+
 	// URL is the URL that the worker with requests.
 	URL string `json:"url"`
 
@@ -76,34 +86,58 @@ type Task struct {
 
 	// Retry is the retry strategy parameters in case of errors.
 	Retry models.Retry `json:"retry"`
+
+	// Callbacks are the URLs to POST to when the Task reaches a terminal state.
+	Callbacks models.Callbacks `json:"callbacks,omitempty"`
+
+	// CircuitBreakerState is the breaker's current state: `closed`, `open`
+	// or `half_open`. Omitted when the Task has no CircuitBreaker configured.
+	CircuitBreakerState string `json:"circuitBreakerState,omitempty"`
+
+	// CircuitBreakerTripCount is the number of times the breaker has tripped.
+	CircuitBreakerTripCount int `json:"circuitBreakerTripCount,omitempty"`
+
+	// CircuitBreakerTrippedAt is the date of the last time the breaker tripped.
+	// Omitted once the breaker has been re-armed.
+	CircuitBreakerTrippedAt string `json:"circuitBreakerTrippedAt,omitempty"`
 }
 
 // NewTaskFromModel returns a Task object for use with the Rest API
 // from a Task model.
 func NewTaskFromModel(task *models.Task) *Task {
+	var circuitBreakerState string
+	if task.CircuitBreaker.WindowSeconds > 0 && task.CircuitBreaker.ErrorRateThreshold > 0 {
+		circuitBreakerState = task.CircuitBreaker.State(time.Now().Unix())
+	}
 	return &Task{
-		ID:          task.ID.Hex(),
-		Created:     task.ID.Time().UTC().Format(time.RFC3339),
-		Application: task.Application,
-		Account:     task.Account.Hex(),
-		Queue:       task.Queue,
-		Name:        task.Name,
-		URL:         task.URL,
-		Method:      task.Method,
-		HTTPAuth:    task.HTTPAuth,
-		Headers:     task.Headers,
-		Payload:     task.Payload,
-		Schedule:    task.Schedule,
-		At:          UnixToRFC3339(int64(task.At / 1000000000)),
-		Status:      task.Status,
-		Executed:    UnixToRFC3339(task.Executed),
-		Active:      &task.Active,
-		Executions:  task.Executions,
-		Errors:      task.Errors,
-		LastSuccess: UnixToRFC3339(task.LastSuccess),
-		LastError:   UnixToRFC3339(task.LastError),
-		ErrorRate:   task.ErrorRate(),
-		Retry:       task.Retry,
+		ID:                      task.ID.Hex(),
+		Created:                 task.ID.Time().UTC().Format(time.RFC3339),
+		Application:             task.Application,
+		Account:                 task.Account.Hex(),
+		Queue:                   task.Queue,
+		Labels:                  task.Labels,
+		PreferredLabels:         task.PreferredLabels,
+		Name:                    task.Name,
+		URL:                     task.URL,
+		Method:                  task.Method,
+		HTTPAuth:                task.HTTPAuth,
+		Headers:                 task.Headers,
+		Payload:                 task.Payload,
+		Schedule:                task.Schedule,
+		At:                      UnixToRFC3339(int64(task.At / 1000000000)),
+		Status:                  task.Status,
+		Executed:                UnixToRFC3339(task.Executed),
+		Active:                  &task.Active,
+		Executions:              task.Executions,
+		Errors:                  task.Errors,
+		LastSuccess:             UnixToRFC3339(task.LastSuccess),
+		LastError:               UnixToRFC3339(task.LastError),
+		ErrorRate:               task.ErrorRate(),
+		Retry:                   task.Retry,
+		Callbacks:               task.Callbacks,
+		CircuitBreakerState:     circuitBreakerState,
+		CircuitBreakerTripCount: task.CircuitBreaker.TripCount,
+		CircuitBreakerTrippedAt: UnixToRFC3339(task.CircuitBreaker.TrippedAt),
 	}
 }
 
@@ -141,7 +175,7 @@ func PutTask(w rest.ResponseWriter, r *rest.Request) {
 		active = *rt.Active
 	}
 	b := GetBase(r)
-	task, err := b.NewTask(accountID, applicationName, taskName, rt.Queue, rt.URL, rt.HTTPAuth, rt.Method, rt.Headers, rt.Payload, rt.Schedule, rt.Retry, active)
+	task, err := b.NewTask(accountID, applicationName, taskName, rt.Queue, rt.URL, rt.HTTPAuth, rt.Method, rt.Headers, rt.Payload, rt.Schedule, rt.Retry, rt.Callbacks, rt.Labels, rt.PreferredLabels, active)
 	if err != nil {
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -218,6 +252,10 @@ func GetTasks(w rest.ResponseWriter, r *rest.Request) {
 	}
 
 	if err := b.GetTasks(accountID, applicationName, lp, lr); err != nil {
+		if err == models.ErrInvalidCursor {
+			rest.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -229,12 +267,15 @@ func GetTasks(w rest.ResponseWriter, r *rest.Request) {
 	for idx, task := range tasks {
 		rt[idx] = NewTaskFromModel(task)
 	}
+	writeListHeaders(w, r, lp, lr)
 	w.WriteJson(models.ListResult{
-		List:    rt,
-		HasMore: lr.HasMore,
-		Total:   lr.Total,
-		Count:   lr.Count,
-		Page:    lr.Page,
-		Pages:   lr.Pages,
+		List:       rt,
+		HasMore:    lr.HasMore,
+		Total:      lr.Total,
+		Count:      lr.Count,
+		Page:       lr.Page,
+		Pages:      lr.Pages,
+		NextCursor: lr.NextCursor,
+		PrevCursor: lr.PrevCursor,
 	})
 }
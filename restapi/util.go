@@ -0,0 +1,27 @@
+package restapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PathAccountID extracts and validates the `:account` path parameter.
+func PathAccountID(r *rest.Request) (bson.ObjectId, error) {
+	account := r.PathParam("account")
+	if !bson.IsObjectIdHex(account) {
+		return "", fmt.Errorf("invalid account %q", account)
+	}
+	return bson.ObjectIdHex(account), nil
+}
+
+// UnixToRFC3339 formats a Unix timestamp, in seconds, as RFC3339. It returns
+// an empty string when ts is zero so that `omitempty` hides unset dates.
+func UnixToRFC3339(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
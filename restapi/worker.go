@@ -0,0 +1,162 @@
+package restapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var errInvalidWorkerID = errors.New("invalid worker id")
+
+// Worker is used for the Rest API.
+type Worker struct {
+	// ID is the Worker ID.
+	ID string `json:"id"`
+
+	// Queue is the name of the queue the Worker pulls Attempts from.
+	Queue string `json:"queue"`
+
+	// Labels are the capabilities advertised by the Worker.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// PostWorker registers a Worker, advertising the Labels it supports.
+func PostWorker(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw := &Worker{}
+	if err := r.DecodeJsonPayload(rw); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	worker, err := b.RegisterWorker(accountID, rw.Queue, rw.Labels)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(&Worker{
+		ID:     worker.ID.Hex(),
+		Queue:  worker.Queue,
+		Labels: worker.Labels,
+	})
+}
+
+func pathWorkerID(r *rest.Request) (bson.ObjectId, error) {
+	id := r.PathParam("worker")
+	if !bson.IsObjectIdHex(id) {
+		return "", errInvalidWorkerID
+	}
+	return bson.ObjectIdHex(id), nil
+}
+
+// ClaimAttempt claims the best pending Attempt matching the Worker's
+// registered Queue and Labels, marking it claimed, or responds 404 when
+// none are eligible.
+func ClaimAttempt(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	workerID, err := pathWorkerID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	worker, err := b.GetWorkerByID(accountID, workerID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if worker == nil {
+		rest.NotFound(w, r)
+		return
+	}
+
+	attempt, err := b.ClaimNextAttempt(accountID, workerID, worker.Queue, worker.Labels)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attempt == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(NewAttemptFromModel(attempt))
+}
+
+// completeAttemptRequest is the body of a CompleteAttempt request: the HTTP
+// result of a claimed Attempt's request, as observed by the worker that ran
+// it. HTTPStatus is ignored when Error is set, since the request never
+// completed.
+type completeAttemptRequest struct {
+	// HTTPStatus is the HTTP status code of the response, if any.
+	HTTPStatus int `json:"httpStatus,omitempty"`
+
+	// ResponseHeaders are the HTTP headers of the response, if any.
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+
+	// ResponseBody is the response body, if any.
+	ResponseBody string `json:"responseBody,omitempty"`
+
+	// Error is the error message if the request could not be completed.
+	Error string `json:"error,omitempty"`
+}
+
+// CompleteAttempt records the HTTP result of an Attempt claimed by the
+// Worker and advances its Task to the next Attempt, or terminal state.
+func CompleteAttempt(w rest.ResponseWriter, r *rest.Request) {
+	accountID, err := PathAccountID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	workerID, err := pathWorkerID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	attemptID, err := pathAttemptID(r)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b := GetBase(r)
+	worker, err := b.GetWorkerByID(accountID, workerID)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if worker == nil {
+		rest.NotFound(w, r)
+		return
+	}
+
+	req := &completeAttemptRequest{}
+	if err := r.DecodeJsonPayload(req); err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	attempt, err := b.CompleteAttempt(accountID, workerID, attemptID, req.HTTPStatus, req.ResponseHeaders, []byte(req.ResponseBody), req.Error)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attempt == nil {
+		rest.NotFound(w, r)
+		return
+	}
+	w.WriteJson(NewAttemptFromModel(attempt))
+}
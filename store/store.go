@@ -0,0 +1,36 @@
+// Package store manages the MongoDB session shared by the models.
+package store
+
+import (
+	"gopkg.in/mgo.v2"
+)
+
+// Store holds the MongoDB session used to open per-request databases.
+type Store struct {
+	session *mgo.Session
+	dbName  string
+}
+
+// New dials url and returns a Store bound to dbName.
+func New(url string, dbName string) (*Store, error) {
+	session, err := mgo.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	session.SetMode(mgo.Monotonic, true)
+	return &Store{
+		session: session,
+		dbName:  dbName,
+	}, nil
+}
+
+// DB returns a new session copy bound to the Store's database. Callers are
+// responsible for closing the returned database's session.
+func (s *Store) DB() *mgo.Database {
+	return s.session.Copy().DB(s.dbName)
+}
+
+// Close terminates the Store's MongoDB session.
+func (s *Store) Close() {
+	s.session.Close()
+}